@@ -0,0 +1,18 @@
+package server
+
+import "server/pkg/packets"
+
+// ClientInterfacer is how the Hub and other clients talk to a connected
+// client without caring which transport (WebSocket, TCP, ...) backs it.
+type ClientInterfacer interface {
+	Id() uint64
+	Initialize(id uint64)
+	ProcessMessage(senderId uint64, message packets.Msg)
+	SocketSend(message packets.Msg)
+	SocketSendAs(message packets.Msg, senderId uint64)
+	PassToPeer(message packets.Msg, peerId uint64)
+	Broadcast(message packets.Msg)
+	ReadPump()
+	WritePump()
+	Close(reason string)
+}
@@ -0,0 +1,51 @@
+package server
+
+import "sync"
+
+// ClientMap is a concurrency-safe registry of connected clients keyed by
+// the id the Hub assigned them.
+type ClientMap struct {
+	mu      sync.RWMutex
+	clients map[uint64]ClientInterfacer
+}
+
+func NewClientMap() *ClientMap {
+	return &ClientMap{
+		clients: make(map[uint64]ClientInterfacer),
+	}
+}
+
+func (m *ClientMap) Get(id uint64) (ClientInterfacer, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	client, exists := m.clients[id]
+	return client, exists
+}
+
+func (m *ClientMap) Add(client ClientInterfacer) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.clients[client.Id()] = client
+}
+
+func (m *ClientMap) Remove(id uint64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.clients, id)
+}
+
+func (m *ClientMap) Len() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return len(m.clients)
+}
+
+// Range calls fn for every currently connected client. fn should not block
+// for long, since it runs while the map's read lock is held.
+func (m *ClientMap) Range(fn func(client ClientInterfacer)) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, client := range m.clients {
+		fn(client)
+	}
+}
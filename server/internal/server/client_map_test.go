@@ -0,0 +1,47 @@
+package server
+
+import (
+	"sync"
+	"testing"
+
+	"server/pkg/packets"
+)
+
+// fakeClient is a minimal ClientInterfacer stand-in for exercising
+// ClientMap and topicMap without spinning up a real transport.
+type fakeClient struct {
+	id uint64
+}
+
+func (f *fakeClient) Id() uint64                                         { return f.id }
+func (f *fakeClient) Initialize(id uint64)                                { f.id = id }
+func (f *fakeClient) ProcessMessage(senderId uint64, message packets.Msg) {}
+func (f *fakeClient) SocketSend(message packets.Msg)                      {}
+func (f *fakeClient) SocketSendAs(message packets.Msg, senderId uint64)   {}
+func (f *fakeClient) PassToPeer(message packets.Msg, peerId uint64)       {}
+func (f *fakeClient) Broadcast(message packets.Msg)                       {}
+func (f *fakeClient) ReadPump()                                           {}
+func (f *fakeClient) WritePump()                                          {}
+func (f *fakeClient) Close(reason string)                                 {}
+
+func TestClientMapConcurrentAddRemoveRange(t *testing.T) {
+	m := NewClientMap()
+
+	var wg sync.WaitGroup
+	for i := uint64(1); i <= 50; i++ {
+		wg.Add(1)
+		go func(id uint64) {
+			defer wg.Done()
+			c := &fakeClient{id: id}
+			m.Add(c)
+			m.Range(func(ClientInterfacer) {})
+			m.Get(id)
+			m.Remove(id)
+		}(i)
+	}
+	wg.Wait()
+
+	if got := m.Len(); got != 0 {
+		t.Fatalf("Len() after concurrent add/remove = %d, want 0", got)
+	}
+}
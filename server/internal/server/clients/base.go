@@ -0,0 +1,134 @@
+package clients
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"server/internal/server"
+	"server/internal/server/metrics"
+	"server/pkg/packets"
+	"sync"
+)
+
+// defaultSendChanSize is how many outgoing packets a client buffers before
+// SocketSendAs starts dropping them.
+const defaultSendChanSize = 256
+
+// baseClient holds the fields and behavior that are identical across every
+// transport-specific client (WebSocketClient, TCPClient): hub bookkeeping,
+// the outgoing queue, topic subscription helpers, and the once-only
+// shutdown signal. Transports embed it and only implement the parts that
+// genuinely differ: ReadPump, WritePump, and how a single packet gets
+// framed onto the wire.
+//
+// self is the embedding type, e.g. *WebSocketClient, so methods here can
+// hand the hub the real ClientInterfacer instead of the embedded
+// baseClient itself. The embedding constructor must set it before the
+// client is used.
+type baseClient struct {
+	self server.ClientInterfacer
+
+	id       uint64
+	hub      *server.Hub
+	conn     io.Closer
+	sendChan chan *packets.Packet
+	logger   *log.Logger
+
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func newBaseClient(hub *server.Hub, conn io.Closer) baseClient {
+	return baseClient{
+		hub:      hub,
+		conn:     conn,
+		sendChan: make(chan *packets.Packet, defaultSendChanSize),
+		logger:   log.New(log.Writer(), "Client unknown: ", log.LstdFlags),
+		done:     make(chan struct{}),
+	}
+}
+
+func (c *baseClient) Id() uint64 {
+	return c.id
+}
+
+func (c *baseClient) Initialize(id uint64) {
+	c.id = id
+	c.logger.SetPrefix(fmt.Sprintf("Client %d: ", c.id))
+	c.SocketSend(packets.NewId(c.id))
+	c.logger.Printf("Sent ID to client")
+}
+
+func (c *baseClient) ProcessMessage(senderId uint64, message packets.Msg) {
+	switch message := message.(type) {
+	case *packets.Packet_SubscribeRequest:
+		c.Subscribe(message.SubscribeRequest.Topic)
+	case *packets.Packet_UnsubscribeRequest:
+		c.Unsubscribe(message.UnsubscribeRequest.Topic)
+	default:
+		if senderId == c.id {
+			// This message was sent by our own client, so broadcast it to everyone else
+			c.Broadcast(message)
+		} else {
+			// Another client interfacer passed this onto us, or it was broadcast from the hub,
+			// so forward it to our own client
+			c.SocketSendAs(message, senderId)
+		}
+	}
+}
+
+// Subscribe joins topic (a zone, chat room, or party channel), so future
+// BroadcastTopic calls for it reach this client.
+func (c *baseClient) Subscribe(topic string) {
+	c.hub.Subscribe(topic, c.self)
+}
+
+// Unsubscribe leaves topic.
+func (c *baseClient) Unsubscribe(topic string) {
+	c.hub.Unsubscribe(topic, c.self)
+}
+
+// BroadcastTopic sends message to every other client subscribed to topic,
+// instead of every client connected to the server.
+func (c *baseClient) BroadcastTopic(topic string, message packets.Msg) {
+	c.hub.BroadcastTopic(topic, c.id, message)
+}
+
+func (c *baseClient) SocketSend(message packets.Msg) {
+	c.SocketSendAs(message, c.id)
+}
+
+func (c *baseClient) SocketSendAs(message packets.Msg, senderId uint64) {
+	select {
+	case c.sendChan <- &packets.Packet{SenderId: senderId, Msg: message}:
+	default:
+		messageType := fmt.Sprintf("%T", message)
+		metrics.SendChanDropsTotal.WithLabelValues(messageType).Inc()
+		c.logger.Printf("Send channel full, dropping message: %s", messageType)
+	}
+}
+
+func (c *baseClient) PassToPeer(message packets.Msg, peerId uint64) {
+	if peer, exists := c.hub.Clients.Get(peerId); exists {
+		peer.ProcessMessage(c.id, message)
+	}
+}
+
+func (c *baseClient) Broadcast(message packets.Msg) {
+	c.hub.BroadcastChan <- &packets.Packet{SenderId: c.id, Msg: message}
+}
+
+// Close tears down the client's connection. It's safe to call more than
+// once or concurrently from both ReadPump and WritePump; only the first
+// call has any effect.
+func (c *baseClient) Close(reason string) {
+	c.closeOnce.Do(func() {
+		c.logger.Printf("Closing client connection because: %s", reason)
+		close(c.done)
+		c.conn.Close()
+
+		// Hand the unregister off to its own goroutine so a hub that's busy
+		// (or itself mid-shutdown) can never make Close block the caller.
+		go func() { c.hub.UnregisterChan <- c.self }()
+	})
+}
@@ -0,0 +1,172 @@
+package clients
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"server/internal/server"
+	"server/internal/server/metrics"
+	"server/pkg/packets"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+)
+
+// lengthPrefixSize is the width, in bytes, of the big-endian length prefix
+// that precedes every frame on the wire.
+const lengthPrefixSize = 4
+
+// TCPClientOptions mirrors WebSocketClientOptions for the raw TCP
+// transport: headless bots, load testers, and native Godot builds that
+// skip the websocket layer entirely.
+type TCPClientOptions struct {
+	MaxMessageSize uint32
+}
+
+func DefaultTCPClientOptions() TCPClientOptions {
+	return TCPClientOptions{
+		MaxMessageSize: DefaultMaxMessageSize,
+	}
+}
+
+// TCPClient speaks a length-prefixed protobuf frame protocol: a 4-byte
+// big-endian length followed by that many bytes of a marshalled
+// packets.Packet.
+type TCPClient struct {
+	baseClient
+
+	conn    net.Conn
+	reader  *bufio.Reader
+	options TCPClientOptions
+}
+
+func NewTCPClient(hub *server.Hub, conn net.Conn) (server.ClientInterfacer, error) {
+	return NewTCPClientWithOptions(hub, conn, DefaultTCPClientOptions())
+}
+
+func NewTCPClientWithOptions(hub *server.Hub, conn net.Conn, options TCPClientOptions) (server.ClientInterfacer, error) {
+	c := &TCPClient{
+		baseClient: newBaseClient(hub, conn),
+		conn:       conn,
+		reader:     bufio.NewReader(conn),
+		options:    options,
+	}
+	c.self = c
+
+	return c, nil
+}
+
+func (c *TCPClient) ReadPump() {
+	start := time.Now()
+	defer func() {
+		metrics.TimeSince(metrics.ReadPumpDuration, start)
+		c.logger.Println("Closing read pump")
+		c.Close("read pump closed")
+	}()
+
+	lengthBuf := make([]byte, lengthPrefixSize)
+	for {
+		if _, err := io.ReadFull(c.reader, lengthBuf); err != nil {
+			if err != io.EOF {
+				c.logger.Printf("error reading frame length: %v", err)
+			}
+			break
+		}
+
+		length := binary.BigEndian.Uint32(lengthBuf)
+		if length > c.options.MaxMessageSize {
+			c.logger.Printf("frame of %d bytes exceeds max message size %d, dropping connection", length, c.options.MaxMessageSize)
+			break
+		}
+
+		data := make([]byte, length)
+		if _, err := io.ReadFull(c.reader, data); err != nil {
+			c.logger.Printf("error reading frame body: %v", err)
+			break
+		}
+
+		packet := &packets.Packet{}
+		if err := proto.Unmarshal(data, packet); err != nil {
+			c.logger.Printf("error unmarshalling data: %v", err)
+			continue
+		}
+
+		// To allow the client to lazily not send the sender ID, we'll assume they want to send it as themselves
+		if packet.SenderId == 0 {
+			packet.SenderId = c.id
+		}
+
+		metrics.PacketsReceivedTotal.WithLabelValues(fmt.Sprintf("%T", packet.Msg)).Inc()
+		c.ProcessMessage(packet.SenderId, packet.Msg)
+	}
+}
+
+func (c *TCPClient) WritePump() {
+	start := time.Now()
+	defer func() {
+		metrics.TimeSince(metrics.WritePumpDuration, start)
+		c.logger.Println("Closing write pump")
+		c.Close("write pump closed")
+	}()
+
+	for {
+		select {
+		case packet := <-c.sendChan:
+			if fatal := c.writePacket(packet); fatal {
+				return
+			}
+		case <-c.done:
+			c.drainSendChan()
+			return
+		case <-c.hub.Context().Done():
+			c.drainSendChan()
+			return
+		}
+	}
+}
+
+// writePacket frames and writes a single packet, tracking it in metrics on
+// success. It reports fatal=true only once the connection itself is no
+// longer usable; a bad payload just gets logged and skipped.
+func (c *TCPClient) writePacket(packet *packets.Packet) (fatal bool) {
+	data, err := proto.Marshal(packet)
+	if err != nil {
+		c.logger.Printf("error marshalling %T packet: %v", packet.Msg, err)
+		return false
+	}
+
+	lengthBuf := make([]byte, lengthPrefixSize)
+	binary.BigEndian.PutUint32(lengthBuf, uint32(len(data)))
+
+	if _, err := c.conn.Write(lengthBuf); err != nil {
+		c.logger.Printf("error writing frame length for %T packet, closing client: %v", packet.Msg, err)
+		return true
+	}
+
+	if _, err := c.conn.Write(data); err != nil {
+		c.logger.Printf("error writing frame body for %T packet, closing client: %v", packet.Msg, err)
+		return true
+	}
+
+	metrics.PacketsSentTotal.WithLabelValues(fmt.Sprintf("%T", packet.Msg)).Inc()
+	return false
+}
+
+// drainSendChan flushes whatever packets are still queued for this client,
+// giving up once DefaultWriteWait has elapsed so a slow or dead peer can't
+// hang up shutdown. It attempts at most cap(sendChan) packets.
+func (c *TCPClient) drainSendChan() {
+	c.conn.SetWriteDeadline(time.Now().Add(DefaultWriteWait))
+	for i := 0; i < cap(c.sendChan); i++ {
+		select {
+		case packet := <-c.sendChan:
+			if fatal := c.writePacket(packet); fatal {
+				return
+			}
+		default:
+			return
+		}
+	}
+}
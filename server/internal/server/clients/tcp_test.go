@@ -0,0 +1,35 @@
+package clients
+
+import (
+	"net"
+	"sync"
+	"testing"
+
+	"server/internal/server"
+)
+
+func TestTCPClientCloseIsIdempotentUnderConcurrentCallers(t *testing.T) {
+	hub := server.NewHub()
+	go hub.ListenAndServe()
+
+	serverConn, clientConn := net.Pipe()
+	defer clientConn.Close()
+
+	c, err := NewTCPClient(hub, serverConn)
+	if err != nil {
+		t.Fatalf("NewTCPClient: %v", err)
+	}
+
+	// Close is documented as safe to call more than once or concurrently,
+	// the same way ReadPump and WritePump both call it on exit. Drive it
+	// from several goroutines at once under go test -race.
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.Close("concurrent close")
+		}()
+	}
+	wg.Wait()
+}
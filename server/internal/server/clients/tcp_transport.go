@@ -0,0 +1,90 @@
+package clients
+
+import (
+	"crypto/tls"
+	"log"
+	"net"
+	"server/internal/server"
+	"sync"
+)
+
+// TCPTransport runs a net.Listener-based accept loop parallel to
+// WebSocketTransport's HTTP upgrader, for native Godot builds without
+// websockets, headless bots, and load testers. Set TLSConfig to serve over
+// TLS instead of plain TCP.
+type TCPTransport struct {
+	Addr      string
+	TLSConfig *tls.Config
+	Options   TCPClientOptions
+
+	mu       sync.Mutex
+	listener net.Listener
+}
+
+func NewTCPTransport(addr string) *TCPTransport {
+	return &TCPTransport{
+		Addr:    addr,
+		Options: DefaultTCPClientOptions(),
+	}
+}
+
+func (t *TCPTransport) Serve(hub *server.Hub) error {
+	var (
+		listener net.Listener
+		err      error
+	)
+
+	if t.TLSConfig != nil {
+		listener, err = tls.Listen("tcp", t.Addr, t.TLSConfig)
+	} else {
+		listener, err = net.Listen("tcp", t.Addr)
+	}
+	if err != nil {
+		return err
+	}
+	t.mu.Lock()
+	t.listener = listener
+	t.mu.Unlock()
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if t.closed() {
+				// Close was called; this is an expected error.
+				return nil
+			}
+			log.Printf("error accepting tcp connection: %v", err)
+			continue
+		}
+
+		client, err := NewTCPClientWithOptions(hub, conn, t.Options)
+		if err != nil {
+			log.Printf("error creating tcp client: %v", err)
+			conn.Close()
+			continue
+		}
+
+		hub.RegisterChan <- client
+		go client.WritePump()
+		go client.ReadPump()
+	}
+}
+
+// closed reports whether Close has already torn down the listener.
+func (t *TCPTransport) closed() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.listener == nil
+}
+
+func (t *TCPTransport) Close() error {
+	t.mu.Lock()
+	listener := t.listener
+	t.listener = nil
+	t.mu.Unlock()
+
+	if listener == nil {
+		return nil
+	}
+	return listener.Close()
+}
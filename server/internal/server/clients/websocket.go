@@ -2,96 +2,125 @@ package clients
 
 import (
 	"fmt"
-	"log"
 	"net/http"
 	"server/internal/server"
+	"server/internal/server/metrics"
 	"server/pkg/packets"
+	"time"
 
+	"github.com/golang/protobuf/proto"
 	"github.com/gorilla/websocket"
-	"google.golang.org/protobuf/proto"
 )
 
-type WebSocketClient struct {
-	id       uint64
-	conn     *websocket.Conn
-	hub      *server.Hub
-	sendChan chan *packets.Packet
-	logger   *log.Logger
-}
-
-func NewWebSocketClient(hub *server.Hub, writer http.ResponseWriter, request *http.Request) (server.ClientInterfacer, error) {
-	upgrader := websocket.Upgrader{
-		ReadBufferSize:  1024,
-		WriteBufferSize: 1024,
-		CheckOrigin:     func(_ *http.Request) bool { return true },
-	}
+const (
+	// DefaultPingPeriod is how often we send a ping to the client. Must be
+	// less than DefaultPongWait.
+	DefaultPingPeriod = 54 * time.Second
+	// DefaultPongWait is how long we'll wait for a pong (or any other
+	// message) before deciding the connection is dead.
+	DefaultPongWait = 60 * time.Second
+	// DefaultWriteWait is how long a single write to the socket is allowed
+	// to take before we give up on it.
+	DefaultWriteWait = 10 * time.Second
+	// DefaultMaxMessageSize is the largest frame we'll accept from a client.
+	DefaultMaxMessageSize = 8192
+	// DefaultCompressionLevel is passed to flate when per-message deflate is
+	// negotiated with a client. See compress/flate for the level's range.
+	DefaultCompressionLevel = 1
+)
 
-	conn, err := upgrader.Upgrade(writer, request, nil)
+// WebSocketClientOptions tunes the keepalive and framing behavior of a
+// WebSocketClient. The zero value is not usable; use
+// DefaultWebSocketClientOptions() to get sane defaults.
+type WebSocketClientOptions struct {
+	PingPeriod     time.Duration
+	PongWait       time.Duration
+	WriteWait      time.Duration
+	MaxMessageSize int64
+
+	// EnableCompression opts this client into RFC 7692 permessage-deflate.
+	// It's only ever negotiated, never forced: a client that didn't offer
+	// the extension in its Sec-WebSocket-Extensions header falls back to
+	// uncompressed frames.
+	EnableCompression bool
+	// CompressionLevel is the flate compression level used once
+	// permessage-deflate is negotiated. Ignored if EnableCompression is
+	// false.
+	CompressionLevel int
+}
 
-	if err != nil {
-		return nil, err
+func DefaultWebSocketClientOptions() WebSocketClientOptions {
+	return WebSocketClientOptions{
+		PingPeriod:        DefaultPingPeriod,
+		PongWait:          DefaultPongWait,
+		WriteWait:         DefaultWriteWait,
+		MaxMessageSize:    DefaultMaxMessageSize,
+		EnableCompression: false,
+		CompressionLevel:  DefaultCompressionLevel,
 	}
+}
 
-	c := &WebSocketClient{
-		hub:      hub,
-		conn:     conn,
-		sendChan: make(chan *packets.Packet, 256),
-		logger:   log.New(log.Writer(), "Client unknown: ", log.LstdFlags),
-	}
+type WebSocketClient struct {
+	baseClient
 
-	return c, nil
+	conn    *websocket.Conn
+	options WebSocketClientOptions
 }
 
-func (c *WebSocketClient) Id() uint64 {
-	return c.id
+func NewWebSocketClient(hub *server.Hub, writer http.ResponseWriter, request *http.Request) (server.ClientInterfacer, error) {
+	return NewWebSocketClientWithOptions(hub, writer, request, DefaultWebSocketClientOptions())
 }
 
-func (c *WebSocketClient) ProcessMessage(senderId uint64, message packets.Msg) {
-	if senderId == c.id {
-		// This message was sent by our own client, so broadcast it to everyone else
-		c.Broadcast(message)
-	} else {
-		// Another client interfacer passed this onto us, or it was broadcast from the hub,
-		// so forward it to our own client
-		c.SocketSendAs(message, senderId)
+func NewWebSocketClientWithOptions(hub *server.Hub, writer http.ResponseWriter, request *http.Request, options WebSocketClientOptions) (server.ClientInterfacer, error) {
+	upgrader := websocket.Upgrader{
+		ReadBufferSize:    1024,
+		WriteBufferSize:   1024,
+		CheckOrigin:       func(_ *http.Request) bool { return true },
+		EnableCompression: options.EnableCompression,
 	}
-}
 
-func (c *WebSocketClient) Initialize(id uint64) {
-	c.id = id
-	c.logger.SetPrefix(fmt.Sprintf("Client %d: ", c.id))
-	c.SocketSend(packets.NewId(c.id))
-	c.logger.Printf("Sent ID to client")
-}
+	conn, err := upgrader.Upgrade(writer, request, nil)
 
-func (c *WebSocketClient) SocketSend(message packets.Msg) {
-	c.SocketSendAs(message, c.id)
-}
+	if err != nil {
+		return nil, err
+	}
 
-func (c *WebSocketClient) SocketSendAs(message packets.Msg, senderId uint64) {
-	select {
-	case c.sendChan <- &packets.Packet{SenderId: senderId, Msg: message}:
-	default:
-		c.logger.Printf("Send channel full, dropping message: %T", message)
+	// The upgrader only negotiates compression if the client offered it; an
+	// older client that didn't ask for permessage-deflate still connects
+	// fine, just without it.
+	if options.EnableCompression {
+		conn.EnableWriteCompression(true)
+		conn.SetCompressionLevel(options.CompressionLevel)
 	}
-}
 
-func (c *WebSocketClient) PassToPeer(message packets.Msg, peerId uint64) {
-	if peer, exists := c.hub.Clients.Get(peerId); exists {
-		peer.ProcessMessage(c.id, message)
+	c := &WebSocketClient{
+		baseClient: newBaseClient(hub, conn),
+		conn:       conn,
+		options:    options,
 	}
-}
+	c.self = c
 
-func (c *WebSocketClient) Broadcast(message packets.Msg) {
-	c.hub.BroadcastChan <- &packets.Packet{SenderId: c.id, Msg: message}
+	return c, nil
 }
 
 func (c *WebSocketClient) ReadPump() {
+	start := time.Now()
 	defer func() {
+		metrics.TimeSince(metrics.ReadPumpDuration, start)
 		c.logger.Println("Closing read pump")
+		// Don't write to conn from here: WritePump owns it as the sole
+		// writer. Just signal done and let WritePump's own <-c.done branch
+		// send the close frame.
 		c.Close("read pump closed")
 	}()
 
+	c.conn.SetReadLimit(c.options.MaxMessageSize)
+	c.conn.SetReadDeadline(time.Now().Add(c.options.PongWait))
+	c.conn.SetPongHandler(func(string) error {
+		c.conn.SetReadDeadline(time.Now().Add(c.options.PongWait))
+		return nil
+	})
+
 	for {
 		_, data, err := c.conn.ReadMessage()
 		if err != nil {
@@ -113,50 +142,107 @@ func (c *WebSocketClient) ReadPump() {
 			packet.SenderId = c.id
 		}
 
+		metrics.PacketsReceivedTotal.WithLabelValues(fmt.Sprintf("%T", packet.Msg)).Inc()
 		c.ProcessMessage(packet.SenderId, packet.Msg)
 	}
 }
 
 func (c *WebSocketClient) WritePump() {
+	start := time.Now()
+	ticker := time.NewTicker(c.options.PingPeriod)
 	defer func() {
+		ticker.Stop()
+		metrics.TimeSince(metrics.WritePumpDuration, start)
 		c.logger.Println("Closing write pump")
 		c.Close("write pump closed")
 	}()
 
-	for packet := range c.sendChan {
-		writer, err := c.conn.NextWriter(websocket.BinaryMessage)
-		if err != nil {
-			c.logger.Printf("error getting writer for %T packet, closing client: %v", packet.Msg, err)
+	for {
+		select {
+		case packet := <-c.sendChan:
+			if fatal := c.writePacket(packet); fatal {
+				return
+			}
+		case <-ticker.C:
+			c.conn.SetWriteDeadline(time.Now().Add(c.options.WriteWait))
+			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				c.logger.Printf("error sending ping, closing client: %v", err)
+				return
+			}
+		case <-c.done:
+			c.drainSendChan()
+			c.sendCloseFrame(websocket.CloseNormalClosure, "connection closed")
+			return
+		case <-c.hub.Context().Done():
+			c.drainSendChan()
+			c.sendCloseFrame(websocket.CloseGoingAway, "server shutting down")
 			return
 		}
+	}
+}
 
-		data, err := proto.Marshal(packet)
-		if err != nil {
-			c.logger.Printf("error marshalling %T packet, closing client: %v", packet.Msg, err)
-			continue
-		}
+// writePacket marshals and writes a single packet, tracking it in metrics on
+// success. It reports fatal=true only when the connection itself is no
+// longer usable (failing to even get a writer); a bad payload or a single
+// failed write just gets logged and skipped.
+func (c *WebSocketClient) writePacket(packet *packets.Packet) (fatal bool) {
+	c.conn.SetWriteDeadline(time.Now().Add(c.options.WriteWait))
 
-		_, err = writer.Write(data)
-		if err != nil {
-			c.logger.Printf("error writing %T packet: %v", packet.Msg, err)
-			continue
-		}
+	writer, err := c.conn.NextWriter(websocket.BinaryMessage)
+	if err != nil {
+		c.logger.Printf("error getting writer for %T packet, closing client: %v", packet.Msg, err)
+		return true
+	}
+
+	data, err := proto.Marshal(packet)
+	if err != nil {
+		c.logger.Printf("error marshalling %T packet: %v", packet.Msg, err)
+		writer.Close()
+		return false
+	}
 
-		writer.Write([]byte{'\n'})
+	if _, err = writer.Write(data); err != nil {
+		c.logger.Printf("error writing %T packet: %v", packet.Msg, err)
+		return false
+	}
 
-		if err = writer.Close(); err != nil {
-			c.logger.Printf("error closing writer for %T packet: %v", packet.Msg, err)
-			continue
-		}
+	writer.Write([]byte{'\n'})
+
+	if err = writer.Close(); err != nil {
+		c.logger.Printf("error closing writer for %T packet: %v", packet.Msg, err)
+		return false
 	}
+
+	metrics.PacketsSentTotal.WithLabelValues(fmt.Sprintf("%T", packet.Msg)).Inc()
+	return false
 }
 
-func (c *WebSocketClient) Close(reason string) {
-	c.logger.Printf("Closing client connection because: %s", reason)
+// drainSendChan flushes whatever packets are still queued for this client,
+// giving up once WriteWait has elapsed so a slow or dead peer can't hang up
+// shutdown.
+func (c *WebSocketClient) drainSendChan() {
+	deadline := time.Now().Add(c.options.WriteWait)
+	for {
+		select {
+		case packet := <-c.sendChan:
+			if time.Now().After(deadline) {
+				continue
+			}
+			if fatal := c.writePacket(packet); fatal {
+				return
+			}
+		default:
+			return
+		}
+	}
+}
 
-	c.hub.UnregisterChan <- c
-	c.conn.Close()
-	if _, closed := <-c.sendChan; !closed {
-		close(c.sendChan)
+// sendCloseFrame writes a WebSocket close frame carrying code and reason so
+// the client knows why it was disconnected.
+func (c *WebSocketClient) sendCloseFrame(code int, reason string) {
+	c.conn.SetWriteDeadline(time.Now().Add(c.options.WriteWait))
+	msg := websocket.FormatCloseMessage(code, reason)
+	if err := c.conn.WriteMessage(websocket.CloseMessage, msg); err != nil {
+		c.logger.Printf("error sending close frame: %v", err)
 	}
-}
\ No newline at end of file
+}
@@ -0,0 +1,55 @@
+package clients
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"server/internal/server"
+
+	"github.com/gorilla/websocket"
+)
+
+// TestWebSocketClientReadPumpCloseDoesNotRaceWithWritePump is a regression
+// test for the chunk0-6 Close() fix: ReadPump used to write a close frame
+// to conn directly on exit while WritePump was concurrently writing pings
+// and packets to the same conn in its own goroutine, which gorilla/
+// websocket detects and panics on. Run with go test -race to catch it.
+func TestWebSocketClientReadPumpCloseDoesNotRaceWithWritePump(t *testing.T) {
+	hub := server.NewHub()
+	go hub.ListenAndServe()
+
+	opts := DefaultWebSocketClientOptions()
+	opts.PingPeriod = 5 * time.Millisecond
+	opts.PongWait = 50 * time.Millisecond
+	opts.WriteWait = 50 * time.Millisecond
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ws", func(w http.ResponseWriter, r *http.Request) {
+		c, err := NewWebSocketClientWithOptions(hub, w, r, opts)
+		if err != nil {
+			t.Errorf("upgrade: %v", err)
+			return
+		}
+		hub.RegisterChan <- c
+		go c.WritePump()
+		go c.ReadPump()
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	wsURL := "ws" + strings.TrimPrefix(srv.URL, "http") + "/ws"
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+
+	// Let a few ping ticks fire so WritePump is actively writing to conn,
+	// then yank the connection out from under ReadPump the way an
+	// ordinary client disconnect would.
+	time.Sleep(20 * time.Millisecond)
+	conn.Close()
+	time.Sleep(50 * time.Millisecond)
+}
@@ -0,0 +1,59 @@
+package clients
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"server/internal/server"
+)
+
+// WebSocketTransport serves clients over an HTTP upgrade, the original
+// (and still default) way Godot clients connect. Its Mux is exported so
+// other HTTP-based subsystems (health checks, metrics) can share the same
+// listener instead of standing up a second one.
+type WebSocketTransport struct {
+	Addr    string
+	Path    string
+	Options WebSocketClientOptions
+	Mux     *http.ServeMux
+
+	httpServer *http.Server
+}
+
+func NewWebSocketTransport(addr, path string) *WebSocketTransport {
+	return &WebSocketTransport{
+		Addr:    addr,
+		Path:    path,
+		Options: DefaultWebSocketClientOptions(),
+		Mux:     http.NewServeMux(),
+	}
+}
+
+func (t *WebSocketTransport) Serve(hub *server.Hub) error {
+	t.Mux.HandleFunc(t.Path, func(w http.ResponseWriter, r *http.Request) {
+		client, err := NewWebSocketClientWithOptions(hub, w, r, t.Options)
+		if err != nil {
+			log.Printf("error upgrading websocket connection: %v", err)
+			return
+		}
+
+		hub.RegisterChan <- client
+		go client.WritePump()
+		go client.ReadPump()
+	})
+
+	t.httpServer = &http.Server{Addr: t.Addr, Handler: t.Mux}
+
+	err := t.httpServer.ListenAndServe()
+	if err == http.ErrServerClosed {
+		return nil
+	}
+	return err
+}
+
+func (t *WebSocketTransport) Close() error {
+	if t.httpServer == nil {
+		return nil
+	}
+	return t.httpServer.Shutdown(context.Background())
+}
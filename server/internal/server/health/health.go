@@ -0,0 +1,69 @@
+// Package health registers /healthz, /readyz, and /metrics so ops tooling
+// (k8s liveness probes, autoscalers, Prometheus) has something better to
+// watch than log lines.
+package health
+
+import (
+	"net/http"
+	"time"
+
+	"server/internal/server"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// maxHealthyBroadcastQueueDepth is how full BroadcastChan can get before
+// /readyz starts reporting the hub as not ready. A hub consistently over
+// this threshold means the run loop can't keep up with incoming traffic.
+const maxHealthyBroadcastQueueDepth = 0.9
+
+// maxHeartbeatAge is how stale hub.Heartbeat() can get before /readyz
+// decides the run loop itself is wedged, rather than just idle. It's a
+// generous multiple of server.HeartbeatInterval so a single slow tick
+// doesn't flap readiness.
+const maxHeartbeatAge = 3 * server.HeartbeatInterval
+
+// Pinger is satisfied by anything readiness should confirm is reachable,
+// such as a database handle. It's optional: pass nil to skip the check.
+type Pinger interface {
+	Ping() error
+}
+
+// RegisterHandlers wires the health endpoints onto mux, which should be the
+// same mux the WebSocket upgrader is registered on.
+func RegisterHandlers(mux *http.ServeMux, hub *server.Hub, db Pinger) {
+	mux.HandleFunc("/healthz", healthzHandler)
+	mux.HandleFunc("/readyz", readyzHandler(hub, db))
+	mux.Handle("/metrics", promhttp.Handler())
+}
+
+// healthzHandler only confirms the process is alive and serving HTTP.
+func healthzHandler(w http.ResponseWriter, _ *http.Request) {
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("ok"))
+}
+
+func readyzHandler(hub *server.Hub, db Pinger) http.HandlerFunc {
+	return func(w http.ResponseWriter, _ *http.Request) {
+		if age := time.Since(hub.Heartbeat()); age > maxHeartbeatAge {
+			http.Error(w, "hub run loop heartbeat stale", http.StatusServiceUnavailable)
+			return
+		}
+
+		depth, cap := hub.BroadcastQueueDepth(), hub.BroadcastQueueCap()
+		if cap > 0 && float64(depth)/float64(cap) >= maxHealthyBroadcastQueueDepth {
+			http.Error(w, "broadcast queue backed up", http.StatusServiceUnavailable)
+			return
+		}
+
+		if db != nil {
+			if err := db.Ping(); err != nil {
+				http.Error(w, "database unreachable: "+err.Error(), http.StatusServiceUnavailable)
+				return
+			}
+		}
+
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("ok"))
+	}
+}
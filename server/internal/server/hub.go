@@ -0,0 +1,192 @@
+package server
+
+import (
+	"context"
+	"log"
+	"sync/atomic"
+	"time"
+
+	"server/internal/server/metrics"
+	"server/pkg/packets"
+)
+
+// DefaultBroadcastQueueSize is how many packets BroadcastChan buffers
+// before a sender blocks. It needs real capacity so BroadcastQueueDepth
+// and BroadcastQueueCap can actually reflect backpressure instead of
+// always reporting zero.
+const DefaultBroadcastQueueSize = 256
+
+// HeartbeatInterval is how often run's select loop proves itself alive
+// even when idle, by bumping the hub's heartbeat timestamp. Readiness
+// checks use this to tell a wedged hub from one that's just quiet.
+const HeartbeatInterval = 5 * time.Second
+
+// Hub owns the set of connected clients and fans packets out between them.
+// It's transport-agnostic: every Transport it's constructed with registers
+// and unregisters clients through the same channels.
+type Hub struct {
+	Clients        *ClientMap
+	BroadcastChan  chan *packets.Packet
+	RegisterChan   chan ClientInterfacer
+	UnregisterChan chan ClientInterfacer
+
+	transports []Transport
+	topics     *topicMap
+	nextId     uint64
+	logger     *log.Logger
+
+	heartbeatNano atomic.Int64
+
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+func NewHub(transports ...Transport) *Hub {
+	ctx, cancel := context.WithCancel(context.Background())
+	h := &Hub{
+		Clients:        NewClientMap(),
+		BroadcastChan:  make(chan *packets.Packet, DefaultBroadcastQueueSize),
+		RegisterChan:   make(chan ClientInterfacer),
+		UnregisterChan: make(chan ClientInterfacer),
+		transports:     transports,
+		topics:         newTopicMap(),
+		logger:         log.New(log.Writer(), "Hub: ", log.LstdFlags),
+		ctx:            ctx,
+		cancel:         cancel,
+	}
+	h.beat()
+	return h
+}
+
+// Context is done once the hub starts shutting down. Clients select on it
+// to know when to disconnect their peer with a server-initiated close.
+func (h *Hub) Context() context.Context {
+	return h.ctx
+}
+
+// Shutdown tells every connected client to disconnect and waits, up to
+// timeout, for them to do so. The hub's register/unregister/broadcast loop
+// keeps running throughout so clients can still unregister as they close.
+func (h *Hub) Shutdown(timeout time.Duration) {
+	h.cancel()
+
+	deadline := time.After(timeout)
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+
+	for h.Clients.Len() > 0 {
+		select {
+		case <-deadline:
+			h.logger.Printf("shutdown timed out with %d clients still connected", h.Clients.Len())
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Subscribe starts routing BroadcastTopic messages for topic to client.
+func (h *Hub) Subscribe(topic string, client ClientInterfacer) {
+	h.topics.subscribe(topic, client)
+}
+
+// Unsubscribe stops routing BroadcastTopic messages for topic to client.
+func (h *Hub) Unsubscribe(topic string, client ClientInterfacer) {
+	h.topics.unsubscribe(topic, client)
+}
+
+// BroadcastTopic sends message to every client subscribed to topic except
+// the one identified by senderId.
+func (h *Hub) BroadcastTopic(topic string, senderId uint64, message packets.Msg) {
+	h.topics.broadcast(topic, senderId, message)
+}
+
+// ClientCount returns the number of currently connected clients.
+func (h *Hub) ClientCount() int {
+	return h.Clients.Len()
+}
+
+// BroadcastQueueDepth returns how many packets are currently buffered in
+// BroadcastChan, waiting for the hub's run loop to fan them out.
+func (h *Hub) BroadcastQueueDepth() int {
+	return len(h.BroadcastChan)
+}
+
+// BroadcastQueueCap returns BroadcastChan's buffer size.
+func (h *Hub) BroadcastQueueCap() int {
+	return cap(h.BroadcastChan)
+}
+
+// Heartbeat returns the last time run's select loop proved it was still
+// responsive, whether that was handling a register/unregister/broadcast or
+// just the idle heartbeat ticker firing. Readiness checks can compare this
+// against time.Now() to detect a wedged hub even when BroadcastChan is
+// empty.
+func (h *Hub) Heartbeat() time.Time {
+	return time.Unix(0, h.heartbeatNano.Load())
+}
+
+func (h *Hub) beat() {
+	h.heartbeatNano.Store(time.Now().UnixNano())
+}
+
+// ListenAndServe starts every transport the hub was constructed with in
+// its own goroutine and then runs the hub's own register/unregister/
+// broadcast loop. That loop has no exit path, so ListenAndServe blocks for
+// the life of the process and does not return.
+func (h *Hub) ListenAndServe() error {
+	for _, transport := range h.transports {
+		go func(t Transport) {
+			if err := t.Serve(h); err != nil {
+				h.logger.Printf("transport stopped: %v", err)
+			}
+		}(transport)
+	}
+
+	h.run()
+	return nil
+}
+
+func (h *Hub) run() {
+	ticker := time.NewTicker(HeartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case client := <-h.RegisterChan:
+			h.onClientRegistered(client)
+		case client := <-h.UnregisterChan:
+			h.onClientUnregistered(client)
+		case packet := <-h.BroadcastChan:
+			h.onBroadcast(packet)
+		case <-ticker.C:
+		}
+		h.beat()
+	}
+}
+
+func (h *Hub) onClientRegistered(client ClientInterfacer) {
+	id := atomic.AddUint64(&h.nextId, 1)
+	client.Initialize(id)
+	h.Clients.Add(client)
+	metrics.ClientsConnected.Set(float64(h.Clients.Len()))
+	h.logger.Printf("Client %d registered, %d clients connected", id, h.Clients.Len())
+}
+
+func (h *Hub) onClientUnregistered(client ClientInterfacer) {
+	if _, exists := h.Clients.Get(client.Id()); !exists {
+		return
+	}
+	h.Clients.Remove(client.Id())
+	h.topics.unsubscribeAll(client)
+	metrics.ClientsConnected.Set(float64(h.Clients.Len()))
+	h.logger.Printf("Client %d unregistered, %d clients connected", client.Id(), h.Clients.Len())
+}
+
+func (h *Hub) onBroadcast(packet *packets.Packet) {
+	metrics.BroadcastQueueDepth.Set(float64(len(h.BroadcastChan)))
+	h.Clients.Range(func(client ClientInterfacer) {
+		if client.Id() != packet.SenderId {
+			client.ProcessMessage(packet.SenderId, packet.Msg)
+		}
+	})
+}
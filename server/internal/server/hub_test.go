@@ -0,0 +1,49 @@
+package server
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHubShutdownDrainsClients(t *testing.T) {
+	hub := NewHub()
+	go hub.run()
+
+	const clientCount = 3
+	clients := make([]*fakeClient, clientCount)
+	for i := range clients {
+		clients[i] = &fakeClient{}
+		hub.RegisterChan <- clients[i]
+	}
+
+	waitFor(t, time.Second, func() bool { return hub.ClientCount() == clientCount })
+
+	// Simulate each client noticing the hub is shutting down and
+	// unregistering itself, the same way ReadPump/WritePump do via
+	// hub.Context().Done() in the real transports.
+	for _, c := range clients {
+		c := c
+		go func() {
+			<-hub.Context().Done()
+			hub.UnregisterChan <- c
+		}()
+	}
+
+	hub.Shutdown(500 * time.Millisecond)
+
+	if got := hub.ClientCount(); got != 0 {
+		t.Fatalf("ClientCount() after Shutdown = %d, want 0", got)
+	}
+}
+
+// waitFor polls cond until it's true or timeout elapses.
+func waitFor(t *testing.T, timeout time.Duration, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(timeout)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for condition")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -0,0 +1,82 @@
+// Package metrics holds the Prometheus collectors shared by the clients
+// and server packages. It's intentionally dependency-free with respect to
+// the rest of the server so both sides can import it without a cycle.
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	ClientsConnected = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: "mmo",
+		Subsystem: "server",
+		Name:      "clients_connected",
+		Help:      "Number of clients currently connected to the hub.",
+	})
+
+	PacketsReceivedTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "mmo",
+		Subsystem: "server",
+		Name:      "packets_received_total",
+		Help:      "Packets received from clients, by message type.",
+	}, []string{"message_type"})
+
+	PacketsSentTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "mmo",
+		Subsystem: "server",
+		Name:      "packets_sent_total",
+		Help:      "Packets sent to clients, by message type.",
+	}, []string{"message_type"})
+
+	SendChanDropsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "mmo",
+		Subsystem: "server",
+		Name:      "send_chan_drops_total",
+		Help:      "Packets dropped because a client's send channel was full, by message type.",
+	}, []string{"message_type"})
+
+	BroadcastQueueDepth = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: "mmo",
+		Subsystem: "server",
+		Name:      "broadcast_queue_depth",
+		Help:      "Number of packets currently buffered in the hub's broadcast channel.",
+	})
+
+	ReadPumpDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: "mmo",
+		Subsystem: "server",
+		Name:      "read_pump_duration_seconds",
+		Help:      "Lifetime of a client's read pump goroutine, from start to connection close.",
+		Buckets:   prometheus.ExponentialBuckets(1, 2, 12), // 1s .. ~34m
+	})
+
+	WritePumpDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: "mmo",
+		Subsystem: "server",
+		Name:      "write_pump_duration_seconds",
+		Help:      "Lifetime of a client's write pump goroutine, from start to connection close.",
+		Buckets:   prometheus.ExponentialBuckets(1, 2, 12),
+	})
+)
+
+func init() {
+	prometheus.MustRegister(
+		ClientsConnected,
+		PacketsReceivedTotal,
+		PacketsSentTotal,
+		SendChanDropsTotal,
+		BroadcastQueueDepth,
+		ReadPumpDuration,
+		WritePumpDuration,
+	)
+}
+
+// TimeSince observes the duration since start on h. It's a small helper so
+// pump functions can defer a single line instead of repeating
+// time.Since(start).Seconds() at every return point.
+func TimeSince(h prometheus.Histogram, start time.Time) {
+	h.Observe(time.Since(start).Seconds())
+}
@@ -0,0 +1,67 @@
+package server
+
+import (
+	"sync"
+
+	"server/pkg/packets"
+)
+
+// topicMap tracks which clients are subscribed to which topics (zones,
+// chat rooms, party channels, ...) so the hub can fan a broadcast out to
+// only the clients that joined it instead of every connected client.
+type topicMap struct {
+	mu     sync.RWMutex
+	topics map[string]map[uint64]ClientInterfacer
+}
+
+func newTopicMap() *topicMap {
+	return &topicMap{
+		topics: make(map[string]map[uint64]ClientInterfacer),
+	}
+}
+
+func (t *topicMap) subscribe(topic string, client ClientInterfacer) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.topics[topic] == nil {
+		t.topics[topic] = make(map[uint64]ClientInterfacer)
+	}
+	t.topics[topic][client.Id()] = client
+}
+
+func (t *topicMap) unsubscribe(topic string, client ClientInterfacer) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.removeLocked(topic, client.Id())
+}
+
+// unsubscribeAll removes a client from every topic it's joined, used when
+// the client disconnects.
+func (t *topicMap) unsubscribeAll(client ClientInterfacer) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for topic := range t.topics {
+		t.removeLocked(topic, client.Id())
+	}
+}
+
+func (t *topicMap) removeLocked(topic string, clientId uint64) {
+	subs, exists := t.topics[topic]
+	if !exists {
+		return
+	}
+	delete(subs, clientId)
+	if len(subs) == 0 {
+		delete(t.topics, topic)
+	}
+}
+
+func (t *topicMap) broadcast(topic string, senderId uint64, message packets.Msg) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	for id, client := range t.topics[topic] {
+		if id != senderId {
+			client.ProcessMessage(senderId, message)
+		}
+	}
+}
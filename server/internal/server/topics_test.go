@@ -0,0 +1,48 @@
+package server
+
+import (
+	"sync"
+	"testing"
+
+	"server/pkg/packets"
+)
+
+func TestTopicMapConcurrentSubscribeUnsubscribeBroadcast(t *testing.T) {
+	tm := newTopicMap()
+
+	var wg sync.WaitGroup
+	for i := uint64(1); i <= 50; i++ {
+		wg.Add(1)
+		go func(id uint64) {
+			defer wg.Done()
+			c := &fakeClient{id: id}
+			tm.subscribe("zone1", c)
+			tm.broadcast("zone1", id, packets.NewId(id))
+			tm.unsubscribe("zone1", c)
+		}(i)
+	}
+	wg.Wait()
+
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+	if subs, exists := tm.topics["zone1"]; exists && len(subs) != 0 {
+		t.Fatalf("zone1 has %d lingering subscribers, want 0", len(subs))
+	}
+}
+
+func TestTopicMapUnsubscribeAll(t *testing.T) {
+	tm := newTopicMap()
+	c := &fakeClient{id: 1}
+
+	tm.subscribe("zone1", c)
+	tm.subscribe("zone2", c)
+	tm.unsubscribeAll(c)
+
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+	for topic, subs := range tm.topics {
+		if _, exists := subs[c.Id()]; exists {
+			t.Fatalf("client still subscribed to %q after unsubscribeAll", topic)
+		}
+	}
+}
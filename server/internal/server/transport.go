@@ -0,0 +1,14 @@
+package server
+
+// Transport accepts incoming connections on some medium (WebSocket, TCP,
+// ...) and hands each one to the Hub as a ClientInterfacer. Implementations
+// live in the clients package; the Hub is given the transports it should
+// serve at construction time so it never has to know which wire protocols
+// are in use.
+type Transport interface {
+	// Serve blocks, accepting and registering clients with hub, until the
+	// transport is closed.
+	Serve(hub *Hub) error
+	// Close stops an in-progress Serve call and releases listener resources.
+	Close() error
+}
@@ -0,0 +1,12 @@
+package packets
+
+// Msg aliases the generated oneof interface so callers outside this
+// package can type message payloads as packets.Msg without reaching into
+// generated internals.
+type Msg = isPacket_Msg
+
+// NewId wraps id in the oneof payload clients receive right after they
+// register with the hub.
+func NewId(id uint64) Msg {
+	return &Packet_Id{Id: &Id{Id: id}}
+}
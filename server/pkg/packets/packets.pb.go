@@ -0,0 +1,138 @@
+// Hand-maintained stand-in for protoc-gen-go output: there's no protoc in
+// this build pipeline yet. It implements only the legacy
+// github.com/golang/protobuf/proto.Message marker (Reset/String/
+// ProtoMessage), which that package's reflection-based legacy support
+// builds a protoreflect.Message from at runtime using the struct tags
+// below. Keep it in sync with packets.proto by hand until real generation
+// is wired up.
+
+package packets
+
+import (
+	proto "github.com/golang/protobuf/proto"
+)
+
+type Id struct {
+	Id uint64 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+}
+
+func (m *Id) Reset()         { *m = Id{} }
+func (m *Id) String() string { return proto.CompactTextString(m) }
+func (*Id) ProtoMessage()    {}
+
+func (m *Id) GetId() uint64 {
+	if m != nil {
+		return m.Id
+	}
+	return 0
+}
+
+type SubscribeRequest struct {
+	Topic string `protobuf:"bytes,1,opt,name=topic,proto3" json:"topic,omitempty"`
+}
+
+func (m *SubscribeRequest) Reset()         { *m = SubscribeRequest{} }
+func (m *SubscribeRequest) String() string { return proto.CompactTextString(m) }
+func (*SubscribeRequest) ProtoMessage()    {}
+
+func (m *SubscribeRequest) GetTopic() string {
+	if m != nil {
+		return m.Topic
+	}
+	return ""
+}
+
+type UnsubscribeRequest struct {
+	Topic string `protobuf:"bytes,1,opt,name=topic,proto3" json:"topic,omitempty"`
+}
+
+func (m *UnsubscribeRequest) Reset()         { *m = UnsubscribeRequest{} }
+func (m *UnsubscribeRequest) String() string { return proto.CompactTextString(m) }
+func (*UnsubscribeRequest) ProtoMessage()    {}
+
+func (m *UnsubscribeRequest) GetTopic() string {
+	if m != nil {
+		return m.Topic
+	}
+	return ""
+}
+
+// isPacket_Msg is satisfied by every message that can ride in a Packet's
+// oneof `msg` field.
+type isPacket_Msg interface {
+	isPacket_Msg()
+}
+
+type Packet_Id struct {
+	Id *Id `protobuf:"bytes,2,opt,name=id,proto3,oneof"`
+}
+
+func (*Packet_Id) isPacket_Msg() {}
+
+type Packet_SubscribeRequest struct {
+	SubscribeRequest *SubscribeRequest `protobuf:"bytes,3,opt,name=subscribe_request,json=subscribeRequest,proto3,oneof"`
+}
+
+func (*Packet_SubscribeRequest) isPacket_Msg() {}
+
+type Packet_UnsubscribeRequest struct {
+	UnsubscribeRequest *UnsubscribeRequest `protobuf:"bytes,4,opt,name=unsubscribe_request,json=unsubscribeRequest,proto3,oneof"`
+}
+
+func (*Packet_UnsubscribeRequest) isPacket_Msg() {}
+
+type Packet struct {
+	SenderId uint64 `protobuf:"varint,1,opt,name=sender_id,json=senderId,proto3" json:"sender_id,omitempty"`
+	// Types that are valid to be assigned to Msg:
+	//	*Packet_Id
+	Msg isPacket_Msg `protobuf_oneof:"msg"`
+}
+
+func (m *Packet) Reset()         { *m = Packet{} }
+func (m *Packet) String() string { return proto.CompactTextString(m) }
+func (*Packet) ProtoMessage()    {}
+
+func (m *Packet) GetSenderId() uint64 {
+	if m != nil {
+		return m.SenderId
+	}
+	return 0
+}
+
+func (m *Packet) GetMsg() isPacket_Msg {
+	if m != nil {
+		return m.Msg
+	}
+	return nil
+}
+
+func (m *Packet) GetId() *Id {
+	if x, ok := m.GetMsg().(*Packet_Id); ok {
+		return x.Id
+	}
+	return nil
+}
+
+func (m *Packet) GetSubscribeRequest() *SubscribeRequest {
+	if x, ok := m.GetMsg().(*Packet_SubscribeRequest); ok {
+		return x.SubscribeRequest
+	}
+	return nil
+}
+
+func (m *Packet) GetUnsubscribeRequest() *UnsubscribeRequest {
+	if x, ok := m.GetMsg().(*Packet_UnsubscribeRequest); ok {
+		return x.UnsubscribeRequest
+	}
+	return nil
+}
+
+// XXX_OneofWrappers lets the legacy proto reflection machinery discover
+// Msg's concrete oneof wrapper types at runtime.
+func (*Packet) XXX_OneofWrappers() []interface{} {
+	return []interface{}{
+		(*Packet_Id)(nil),
+		(*Packet_SubscribeRequest)(nil),
+		(*Packet_UnsubscribeRequest)(nil),
+	}
+}